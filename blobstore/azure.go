@@ -0,0 +1,85 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// AzureProvider stores objects as block blobs in an Azure Storage container.
+type AzureProvider struct {
+	accountName string
+	container   azblob.ContainerURL
+}
+
+// NewAzureProvider builds a Provider backed by cfg.Bucket as the container
+// name, authenticating with cfg.AccountName/cfg.AccountKey.
+func NewAzureProvider(cfg Config) (*AzureProvider, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("blobstore: azure provider requires a bucket (container name)")
+	}
+	if cfg.AccountName == "" || cfg.AccountKey == "" {
+		return nil, fmt.Errorf("blobstore: azure provider requires account_name and account_key")
+	}
+
+	credential, err := azblob.NewSharedKeyCredential(cfg.AccountName, cfg.AccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to create azure credential, %v", err)
+	}
+	pipeline := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+
+	containerURL, err := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net/%s", cfg.AccountName, cfg.Bucket))
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: invalid azure container url, %v", err)
+	}
+
+	return &AzureProvider{
+		accountName: cfg.AccountName,
+		container:   azblob.NewContainerURL(*containerURL, pipeline),
+	}, nil
+}
+
+// Put uploads reader to key as a block blob. acl is accepted to satisfy
+// Provider but unused: container-level access is configured out of band in
+// Azure, not per object.
+func (p *AzureProvider) Put(ctx context.Context, key string, reader io.Reader, contentType, acl string) (string, error) {
+	blobURL := p.container.NewBlockBlobURL(key)
+	_, err := azblob.UploadStreamToBlockBlob(ctx, reader, blobURL, azblob.UploadStreamToBlockBlobOptions{
+		BufferSize: 4 * 1024 * 1024,
+		MaxBuffers: 4,
+		BlobHTTPHeaders: azblob.BlobHTTPHeaders{
+			ContentType: contentType,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("blobstore: failed to upload %s, %v", key, err)
+	}
+	return p.PublicURL(key), nil
+}
+
+// Delete removes key from the container.
+func (p *AzureProvider) Delete(ctx context.Context, key string) error {
+	blobURL := p.container.NewBlockBlobURL(key)
+	if _, err := blobURL.Delete(ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{}); err != nil {
+		return fmt.Errorf("blobstore: failed to delete %s, %v", key, err)
+	}
+	return nil
+}
+
+// Get opens key for reading.
+func (p *AzureProvider) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	blobURL := p.container.NewBlockBlobURL(key)
+	resp, err := blobURL.Download(ctx, 0, azblob.CountToEnd, azblob.BlobAccessConditions{}, false)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to get %s, %v", key, err)
+	}
+	return resp.Body(azblob.RetryReaderOptions{}), nil
+}
+
+// PublicURL returns the https URL key is served at.
+func (p *AzureProvider) PublicURL(key string) string {
+	return p.container.NewBlockBlobURL(key).URL().String()
+}
@@ -0,0 +1,35 @@
+package blobstore
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// contentTypeOverrides covers mobile-specific extensions that either have
+// no entry in the system mime database or resolve to the wrong type on it
+// (e.g. .plist is commonly mapped to "application/x-plist" or missing
+// entirely, depending on platform).
+var contentTypeOverrides = map[string]string{
+	".ipa":          "application/octet-stream",
+	".apk":          "application/vnd.android.package-archive",
+	".plist":        "application/xml",
+	".mobileconfig": "application/x-apple-aspen-config",
+}
+
+// DetectContentType resolves the content type for the file at path. It
+// consults contentTypeOverrides first, then the system mime database via
+// mime.TypeByExtension, and finally falls back to sniffing head (expected
+// to be the first 512 bytes of the file, per http.DetectContentType) when
+// the extension is unrecognized.
+func DetectContentType(path string, head []byte) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ct, ok := contentTypeOverrides[ext]; ok {
+		return ct
+	}
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct
+	}
+	return http.DetectContentType(head)
+}
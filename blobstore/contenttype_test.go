@@ -0,0 +1,35 @@
+package blobstore
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		path string
+		head []byte
+		want string
+	}{
+		{"build/app.ipa", nil, "application/octet-stream"},
+		{"build/app.apk", nil, "application/vnd.android.package-archive"},
+		{"build/app.plist", nil, "application/xml"},
+		{"build/profile.mobileconfig", nil, "application/x-apple-aspen-config"},
+		{"build/index.html", nil, "text/html; charset=utf-8"},
+		{"build/unknown-ext.xyz123", []byte("%PDF-1.4"), "application/pdf"},
+	}
+
+	for _, tt := range tests {
+		if got := DetectContentType(tt.path, tt.head); got != tt.want {
+			t.Errorf("DetectContentType(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// The naive "filename contains html" heuristic this resolver replaces
+	// would have misclassified this as text/html. The exact type returned
+	// depends on the host's mime database (e.g. "application/zip" vs
+	// "application/octet-stream"), so only assert it isn't that.
+	if got := DetectContentType("foo-html-report.zip", nil); got == "text/html" || strings.HasPrefix(got, "text/html;") {
+		t.Errorf("DetectContentType(%q) = %q, misclassified as html", "foo-html-report.zip", got)
+	}
+}
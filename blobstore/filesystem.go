@@ -0,0 +1,73 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// FileProvider stores objects on the local filesystem under BaseDir, and
+// serves them from BaseURL. It exists mainly for on-prem hosting and for
+// local testing of the upload pipeline without touching a real cloud
+// account.
+type FileProvider struct {
+	baseDir string
+	baseURL string
+}
+
+// NewFileProvider builds a Provider rooted at cfg.BaseDir, serving objects
+// from cfg.BaseURL (e.g. a path an nginx/caddy instance exposes).
+func NewFileProvider(cfg Config) (*FileProvider, error) {
+	if cfg.BaseDir == "" {
+		return nil, fmt.Errorf("blobstore: filesystem provider requires base_dir")
+	}
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("blobstore: filesystem provider requires base_url")
+	}
+	return &FileProvider{baseDir: cfg.BaseDir, baseURL: cfg.BaseURL}, nil
+}
+
+// Put writes reader to BaseDir/key, creating any intermediate directories.
+// contentType and acl are accepted to satisfy Provider but otherwise unused,
+// since a local file has neither.
+func (p *FileProvider) Put(ctx context.Context, key string, reader io.Reader, contentType, acl string) (string, error) {
+	dest := filepath.Join(p.baseDir, key)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("blobstore: failed to create directory for %s, %v", key, err)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", fmt.Errorf("blobstore: failed to create %s, %v", key, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, reader); err != nil {
+		return "", fmt.Errorf("blobstore: failed to write %s, %v", key, err)
+	}
+	return p.PublicURL(key), nil
+}
+
+// Delete removes BaseDir/key.
+func (p *FileProvider) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(filepath.Join(p.baseDir, key)); err != nil {
+		return fmt.Errorf("blobstore: failed to delete %s, %v", key, err)
+	}
+	return nil
+}
+
+// Get opens BaseDir/key for reading.
+func (p *FileProvider) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(p.baseDir, key))
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to open %s, %v", key, err)
+	}
+	return f, nil
+}
+
+// PublicURL joins BaseURL and key.
+func (p *FileProvider) PublicURL(key string) string {
+	return p.baseURL + "/" + key
+}
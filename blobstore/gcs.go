@@ -0,0 +1,84 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// GCSProvider stores objects in a Google Cloud Storage bucket.
+type GCSProvider struct {
+	bucket string
+	client *storage.Client
+}
+
+// NewGCSProvider builds a Provider backed by cfg.Bucket. When
+// cfg.CredentialsFile is set it is used as the service account key;
+// otherwise the client falls back to Application Default Credentials.
+func NewGCSProvider(cfg Config) (*GCSProvider, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("blobstore: gcs provider requires a bucket")
+	}
+
+	ctx := context.Background()
+	var opts []option.ClientOption
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to create gcs client, %v", err)
+	}
+
+	return &GCSProvider{bucket: cfg.Bucket, client: client}, nil
+}
+
+// Put uploads reader to key. When acl is "public-read" the object's ACL is
+// set to world-readable after the write completes.
+func (p *GCSProvider) Put(ctx context.Context, key string, reader io.Reader, contentType, acl string) (string, error) {
+	obj := p.client.Bucket(p.bucket).Object(key)
+	w := obj.NewWriter(ctx)
+	w.ContentType = contentType
+
+	if _, err := io.Copy(w, reader); err != nil {
+		w.Close()
+		return "", fmt.Errorf("blobstore: failed to upload %s, %v", key, err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("blobstore: failed to upload %s, %v", key, err)
+	}
+
+	if acl == "public-read" {
+		if err := obj.ACL().Set(ctx, storage.AllUsers, storage.RoleReader); err != nil {
+			return "", fmt.Errorf("blobstore: failed to set public acl on %s, %v", key, err)
+		}
+	}
+
+	return p.PublicURL(key), nil
+}
+
+// Delete removes key from the bucket.
+func (p *GCSProvider) Delete(ctx context.Context, key string) error {
+	if err := p.client.Bucket(p.bucket).Object(key).Delete(ctx); err != nil {
+		return fmt.Errorf("blobstore: failed to delete %s, %v", key, err)
+	}
+	return nil
+}
+
+// Get opens key for reading.
+func (p *GCSProvider) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := p.client.Bucket(p.bucket).Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to get %s, %v", key, err)
+	}
+	return r, nil
+}
+
+// PublicURL returns the https URL key is served at.
+func (p *GCSProvider) PublicURL(key string) string {
+	return "https://storage.googleapis.com/" + p.bucket + "/" + key
+}
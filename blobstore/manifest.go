@@ -0,0 +1,37 @@
+package blobstore
+
+// ManifestFile is the name manifest.json is uploaded as, alongside the
+// rest of a build's assets.
+const ManifestFile = "manifest.json"
+
+// ManifestEntry records everything needed to tell, on a later run, whether
+// the asset at Key still matches what's already been uploaded.
+type ManifestEntry struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	ETag        string `json:"etag,omitempty"`
+	ContentType string `json:"content_type"`
+}
+
+// Manifest is the machine-readable index UploadAssets writes to
+// destBaseDir/version/build/manifest.json, and reads back on the next run
+// to decide which assets are already up to date.
+type Manifest struct {
+	Version string          `json:"version"`
+	Build   string          `json:"build"`
+	Assets  []ManifestEntry `json:"assets"`
+}
+
+// find returns the entry for key, if any.
+func (m *Manifest) find(key string) (ManifestEntry, bool) {
+	if m == nil {
+		return ManifestEntry{}, false
+	}
+	for _, e := range m.Assets {
+		if e.Key == key {
+			return e, true
+		}
+	}
+	return ManifestEntry{}, false
+}
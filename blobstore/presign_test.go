@@ -0,0 +1,50 @@
+package blobstore
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func newTestS3Provider(t *testing.T) *S3Provider {
+	t.Helper()
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("AKIDEXAMPLE", "secret", ""),
+	}))
+	return newS3Provider(Config{Bucket: "my-bucket"}, s3.New(sess))
+}
+
+func TestS3ProviderPresignedURL(t *testing.T) {
+	p := newTestS3Provider(t)
+	expiry := 15 * time.Minute
+
+	raw, err := p.PresignedURL(context.Background(), "build/app.plist", expiry)
+	if err != nil {
+		t.Fatalf("PresignedURL returned error: %v", err)
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("presigned URL is not a valid URL: %v", err)
+	}
+	if parsed.Scheme != "https" {
+		t.Fatalf("presigned URL scheme = %q, want https", parsed.Scheme)
+	}
+
+	expiresIn := parsed.Query().Get("X-Amz-Expires")
+	got, err := strconv.Atoi(expiresIn)
+	if err != nil {
+		t.Fatalf("X-Amz-Expires is not numeric: %q", expiresIn)
+	}
+	if int64(got) != int64(expiry.Seconds()) {
+		t.Fatalf("X-Amz-Expires = %d, want %d", got, int64(expiry.Seconds()))
+	}
+}
@@ -0,0 +1,169 @@
+// The MIT License (MIT)
+
+// Copyright (c) John Bryan Sazon <bryansazon@hotmail.com>
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package blobstore provides a pluggable storage backend for gota so that
+// generated mobile build assets (ipa/apk, plist, index.html, icons) can be
+// hosted somewhere other than AWS S3.
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Provider is implemented by every supported storage backend. UploadAssets
+// and UploadFile are written against this interface instead of talking to
+// any single cloud SDK directly, so adding a new backend never touches the
+// upload/manifest logic.
+type Provider interface {
+	// Put uploads the contents of reader to key and returns the URL the
+	// object can be reached at afterwards (which may or may not be public,
+	// depending on acl and the backend).
+	Put(ctx context.Context, key string, reader io.Reader, contentType, acl string) (url string, err error)
+	// Delete removes the object stored at key.
+	Delete(ctx context.Context, key string) error
+	// Get opens the object stored at key for reading. The caller is
+	// responsible for closing the returned reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// PublicURL returns the URL key would be served at, without making any
+	// network call. It is used to populate app.DownloadURL/app.PlistURL
+	// before the corresponding object has actually been uploaded.
+	PublicURL(key string) string
+}
+
+// HeadProvider is implemented by providers that can cheaply confirm an
+// object still exists (and fetch its ETag) without downloading it.
+// UploadAssets uses it to re-upload, rather than skip, an asset whose
+// manifest digest hasn't changed but whose remote object is missing.
+type HeadProvider interface {
+	Head(ctx context.Context, key string) (exists bool, etag string, err error)
+}
+
+// Config selects and configures a Provider. It is typically decoded from the
+// `[blobstore]` table of config.toml, e.g.:
+//
+//	[blobstore]
+//	type   = "s3"
+//	bucket = "my-bucket"
+//	region = "us-east-1"
+type Config struct {
+	// Type selects the backend: "s3" (also used for MinIO/Spaces/Ceph via
+	// Endpoint), "filesystem", "gcs", or "azure". Defaults to "s3".
+	Type string `toml:"type"`
+
+	// Bucket is the S3/GCS bucket name, or the Azure container name.
+	Bucket string `toml:"bucket"`
+
+	// Region is the AWS region. Ignored by the other providers.
+	Region string `toml:"region"`
+
+	// Endpoint overrides the default S3 endpoint, for S3-compatible
+	// services such as MinIO, DigitalOcean Spaces, or Ceph RGW.
+	Endpoint string `toml:"endpoint"`
+
+	// S3ForcePathStyle addresses objects as "endpoint/bucket/key" instead
+	// of "bucket.endpoint/key", which most S3-compatible services require.
+	S3ForcePathStyle bool `toml:"s3_force_path_style"`
+
+	// Profile selects a named profile from SharedCredentialsFile (or the
+	// default AWS credentials file when SharedCredentialsFile is empty).
+	// Ignored by the other providers.
+	Profile string `toml:"profile"`
+
+	// SharedCredentialsFile overrides the default `~/.aws/credentials`
+	// path the S3 provider reads Profile from.
+	SharedCredentialsFile string `toml:"shared_credentials_file"`
+
+	// ServerSideEncryption selects the S3 provider's encryption-at-rest
+	// mode: "AES256" or "aws:kms". Defaults to "AES256" when empty; no
+	// value turns encryption at rest off.
+	ServerSideEncryption string `toml:"server_side_encryption"`
+
+	// SSEKMSKeyID is the KMS key ID to encrypt with when
+	// ServerSideEncryption is "aws:kms". Ignored otherwise.
+	SSEKMSKeyID string `toml:"sse_kms_key_id"`
+
+	// StorageClass selects the S3 storage class objects are written with
+	// (e.g. "STANDARD", "STANDARD_IA", "REDUCED_REDUNDANCY"). Defaults to
+	// the S3 account default when empty.
+	StorageClass string `toml:"storage_class"`
+
+	// CacheControl, when set, is sent as the Cache-Control header on every
+	// object the S3 provider uploads.
+	CacheControl string `toml:"cache_control"`
+
+	// BaseDir is the root directory the filesystem provider writes under.
+	BaseDir string `toml:"base_dir"`
+
+	// BaseURL is the URL prefix objects are served from. Required by the
+	// filesystem provider (e.g. when fronted by nginx). The S3, GCS and
+	// Azure providers ignore it and always use their default URL pattern.
+	BaseURL string `toml:"base_url"`
+
+	// CredentialsFile points at a GCS service account JSON key file.
+	// Ignored by the other providers, including Azure.
+	CredentialsFile string `toml:"credentials_file"`
+
+	// AccountName and AccountKey authenticate against Azure Blob Storage.
+	AccountName string `toml:"account_name"`
+	AccountKey  string `toml:"account_key"`
+
+	// PartSize and Concurrency tune the S3 provider's multipart uploader.
+	// They default to the aws-sdk-go s3manager defaults (5MB parts, 5
+	// concurrent parts) when left zero.
+	PartSize    int64 `toml:"part_size"`
+	Concurrency int   `toml:"concurrency"`
+
+	// LeavePartsOnError stops the S3 provider from aborting an in-progress
+	// multipart upload when a part fails, which is useful for debugging
+	// failed uploads of large IPA/APK files.
+	LeavePartsOnError bool `toml:"leave_parts_on_error"`
+
+	// MaxRetries bounds how many times the S3 provider retries a transient
+	// upload error before giving up. Defaults to 3 when zero.
+	MaxRetries int `toml:"max_retries"`
+
+	// ProgressFunc, when set, is called as the S3 provider streams a file,
+	// reporting bytesWritten out of total so callers can drive a CLI
+	// progress bar for large IPA/APK uploads. It is set programmatically
+	// and has no config.toml equivalent.
+	ProgressFunc func(bytesWritten, total int64) `toml:"-"`
+}
+
+// New returns the Provider selected by cfg.Type. An unrecognized type is
+// treated as a configuration error rather than silently falling back to S3,
+// so a typo in config.toml is caught at startup.
+func New(cfg Config) (Provider, error) {
+	switch cfg.Type {
+	case "s3", "":
+		return NewS3Provider(cfg)
+	case "filesystem":
+		return NewFileProvider(cfg)
+	case "gcs":
+		return NewGCSProvider(cfg)
+	case "azure":
+		return NewAzureProvider(cfg)
+	default:
+		return nil, fmt.Errorf("blobstore: unknown provider type %q", cfg.Type)
+	}
+}
@@ -0,0 +1,304 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// defaultMaxRetries is how many times Put retries a transient upload error
+// when Config.MaxRetries is left at zero.
+const defaultMaxRetries = 3
+
+// S3Provider stores objects in AWS S3, or any S3-compatible service (MinIO,
+// DigitalOcean Spaces, Ceph RGW) reachable through cfg.Endpoint.
+type S3Provider struct {
+	bucket       string
+	endpoint     string
+	pathStyle    bool
+	maxRetries   int
+	sse          string
+	sseKMSKeyID  string
+	storageClass string
+	cacheControl string
+	progress     func(bytesWritten, total int64)
+	sess         *session.Session
+	uploader     *s3manager.Uploader
+	client       s3iface.S3API
+}
+
+// NewS3Provider builds a Provider backed by cfg.Bucket, creating a single
+// *session.Session that is reused for every upload rather than one per
+// file. cfg.Endpoint and cfg.S3ForcePathStyle only need to be set when
+// targeting an S3-compatible service rather than AWS itself. cfg.Profile
+// and cfg.SharedCredentialsFile select a named profile instead of the
+// default credential chain.
+func NewS3Provider(cfg Config) (*S3Provider, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("blobstore: s3 provider requires a bucket")
+	}
+
+	awsCfg := aws.NewConfig()
+	if cfg.Region != "" {
+		awsCfg = awsCfg.WithRegion(cfg.Region)
+	}
+	if cfg.Endpoint != "" {
+		awsCfg = awsCfg.WithEndpoint(cfg.Endpoint).WithS3ForcePathStyle(cfg.S3ForcePathStyle)
+	}
+	if cfg.Profile != "" {
+		awsCfg = awsCfg.WithCredentials(credentials.NewSharedCredentials(cfg.SharedCredentialsFile, cfg.Profile))
+	}
+
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to create aws session, %v", err)
+	}
+
+	p := newS3Provider(cfg, s3.New(sess))
+	p.sess = sess
+	return p, nil
+}
+
+// newS3Provider builds an S3Provider around an already-constructed
+// s3iface.S3API client, so tests can inject a fake instead of a real AWS
+// session.
+func newS3Provider(cfg Config, client s3iface.S3API) *S3Provider {
+	uploader := s3manager.NewUploaderWithClient(client, func(u *s3manager.Uploader) {
+		if cfg.PartSize > 0 {
+			u.PartSize = cfg.PartSize
+		}
+		if cfg.Concurrency > 0 {
+			u.Concurrency = cfg.Concurrency
+		}
+		u.LeavePartsOnError = cfg.LeavePartsOnError
+	})
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+
+	sse := cfg.ServerSideEncryption
+	if sse == "" {
+		sse = "AES256"
+	}
+
+	return &S3Provider{
+		bucket:       cfg.Bucket,
+		endpoint:     cfg.Endpoint,
+		pathStyle:    cfg.S3ForcePathStyle,
+		maxRetries:   maxRetries,
+		sse:          sse,
+		sseKMSKeyID:  cfg.SSEKMSKeyID,
+		storageClass: cfg.StorageClass,
+		cacheControl: cfg.CacheControl,
+		progress:     cfg.ProgressFunc,
+		uploader:     uploader,
+		client:       client,
+	}
+}
+
+// Put streams reader to key with the given content type and ACL (e.g.
+// "public-read" or "private"), retrying transient failures with a backoff.
+// Retries rewind reader to where it started, so a reader that is not an
+// io.Seeker gets a single attempt. When reader is an io.Seeker (as *os.File is), its size is used to report
+// progress through Config.ProgressFunc as the upload streams; reader is
+// never buffered into memory up front.
+func (p *S3Provider) Put(ctx context.Context, key string, reader io.Reader, contentType, acl string) (string, error) {
+	seeker, seekable := reader.(io.Seeker)
+	var startOffset int64
+	if seekable {
+		startOffset, _ = seeker.Seek(0, io.SeekCurrent)
+	}
+
+	var pr *progressReader
+	body := reader
+	if p.progress != nil {
+		if total, ok := sizeOf(reader); ok {
+			pr = &progressReader{r: reader, total: total, onProgress: p.progress}
+			body = pr
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= p.maxRetries; attempt++ {
+		if attempt > 0 {
+			if !seekable {
+				break
+			}
+			if _, err := seeker.Seek(startOffset, io.SeekStart); err != nil {
+				break
+			}
+			if pr != nil {
+				pr.read = 0
+			}
+			time.Sleep(backoff(attempt))
+		}
+
+		input := &s3manager.UploadInput{
+			ACL:         aws.String(acl),
+			Bucket:      aws.String(p.bucket),
+			Key:         aws.String(key),
+			Body:        body,
+			ContentType: aws.String(contentType),
+		}
+		if p.sse != "" {
+			input.ServerSideEncryption = aws.String(p.sse)
+			if p.sse == s3.ServerSideEncryptionAwsKms {
+				input.SSEKMSKeyId = aws.String(p.sseKMSKeyID)
+			}
+		}
+		if p.storageClass != "" {
+			input.StorageClass = aws.String(p.storageClass)
+		}
+		if p.cacheControl != "" {
+			input.CacheControl = aws.String(p.cacheControl)
+		}
+
+		result, err := p.uploader.UploadWithContext(ctx, input)
+		if err == nil {
+			return aws.StringValue(&result.Location), nil
+		}
+		lastErr = err
+		if !isTransient(err) {
+			break
+		}
+	}
+	return "", fmt.Errorf("blobstore: failed to upload %s, %v", key, lastErr)
+}
+
+// Delete removes key from the bucket.
+func (p *S3Provider) Delete(ctx context.Context, key string) error {
+	_, err := p.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("blobstore: failed to delete %s, %v", key, err)
+	}
+	return nil
+}
+
+// Head reports whether key exists in the bucket and, if so, its ETag.
+func (p *S3Provider) Head(ctx context.Context, key string) (bool, string, error) {
+	result, err := p.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && (awsErr.Code() == s3.ErrCodeNoSuchKey || awsErr.Code() == "NotFound") {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("blobstore: failed to head %s, %v", key, err)
+	}
+	return true, aws.StringValue(result.ETag), nil
+}
+
+// Get opens key for reading.
+func (p *S3Provider) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := p.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to get %s, %v", key, err)
+	}
+	return result.Body, nil
+}
+
+// PublicURL returns the URL key is served at: https://bucket.s3.amazonaws.com/key
+// by default or, with a custom endpoint, endpoint/bucket/key for path-style
+// addressing and https://bucket.endpoint/key otherwise.
+func (p *S3Provider) PublicURL(key string) string {
+	if p.endpoint != "" {
+		if p.pathStyle {
+			return p.endpoint + "/" + p.bucket + "/" + key
+		}
+		return "https://" + p.bucket + "." + p.endpoint + "/" + key
+	}
+	return "https://" + p.bucket + ".s3.amazonaws.com/" + key
+}
+
+// PresignedURL returns a time-limited https URL for key, signed to expire
+// after expiry. It is used in ModePrivate, where objects aren't otherwise
+// reachable through PublicURL.
+func (p *S3Provider) PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	req, _ := p.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+	})
+	url, err := req.Presign(expiry)
+	if err != nil {
+		return "", fmt.Errorf("blobstore: failed to presign %s, %v", key, err)
+	}
+	return url, nil
+}
+
+// sizeOf returns the remaining size of r when it is an io.Seeker, without
+// disturbing a reader that doesn't support seeking.
+func sizeOf(r io.Reader) (int64, bool) {
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return 0, false
+	}
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, false
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := seeker.Seek(current, io.SeekStart); err != nil {
+		return 0, false
+	}
+	return end - current, true
+}
+
+// progressReader wraps an io.Reader, reporting cumulative bytes read to
+// onProgress as the upload streams.
+type progressReader struct {
+	r          io.Reader
+	total      int64
+	read       int64
+	onProgress func(bytesWritten, total int64)
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	if n > 0 {
+		p.read += int64(n)
+		p.onProgress(p.read, p.total)
+	}
+	return n, err
+}
+
+// backoff returns a quadratically growing delay (attempt² × 100ms) for the
+// given retry attempt (1-indexed).
+func backoff(attempt int) time.Duration {
+	return time.Duration(attempt) * time.Duration(attempt) * 100 * time.Millisecond
+}
+
+// isTransient reports whether err is worth retrying, as opposed to a
+// permanent failure like a bad request or missing bucket.
+func isTransient(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return true
+	}
+	switch awsErr.Code() {
+	case "RequestError", "RequestTimeout", "RequestTimeTooSkewed", "InternalError", "SlowDown", "ServiceUnavailable":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// fakeS3API implements s3iface.S3API by embedding it (so only the methods
+// exercised by S3Provider need overriding) and recording every PutObject
+// call it receives.
+type fakeS3API struct {
+	s3iface.S3API
+
+	puts      []*s3.PutObjectInput
+	bodies    [][]byte
+	failTimes int // number of times PutObject should fail before succeeding
+}
+
+// PutObjectRequest is what s3manager.Uploader calls for a single-part
+// upload. The returned request's Send handler stands in for the network.
+func (f *fakeS3API) PutObjectRequest(in *s3.PutObjectInput) (*request.Request, *s3.PutObjectOutput) {
+	out := &s3.PutObjectOutput{}
+	op := &request.Operation{Name: "PutObject", HTTPMethod: "PUT"}
+	req := request.New(aws.Config{}, metadata.ClientInfo{Endpoint: "https://s3.test"}, request.Handlers{}, nil, op, in, out)
+	req.Handlers.Send.PushBack(func(r *request.Request) {
+		if f.failTimes > 0 {
+			f.failTimes--
+			r.Error = awserr.New("InternalError", "fake transient failure", nil)
+			return
+		}
+
+		body, err := ioutil.ReadAll(in.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+		f.puts = append(f.puts, in)
+		f.bodies = append(f.bodies, body)
+	})
+	return req, out
+}
+
+func newTestFile(t *testing.T, content []byte) *os.File {
+	t.Helper()
+	f, err := ioutil.TempFile("", "blobstore-s3-test")
+	if err != nil {
+		t.Fatalf("failed to create temp file, %v", err)
+	}
+	t.Cleanup(func() {
+		f.Close()
+		os.Remove(f.Name())
+	})
+	if _, err := f.Write(content); err != nil {
+		t.Fatalf("failed to write temp file, %v", err)
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		t.Fatalf("failed to rewind temp file, %v", err)
+	}
+	return f
+}
+
+func TestS3ProviderPutStreamsFromDisk(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 1024)
+	f := newTestFile(t, content)
+	fake := &fakeS3API{}
+	p := newS3Provider(Config{Bucket: "my-bucket"}, fake)
+
+	if _, err := p.Put(context.Background(), "build/app.ipa", f, "application/octet-stream", "public-read"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	if len(fake.puts) != 1 {
+		t.Fatalf("expected exactly one PutObject call, got %d", len(fake.puts))
+	}
+	if !bytes.Equal(fake.bodies[0], content) {
+		t.Fatalf("uploaded body does not match source file content")
+	}
+	if _, isBuffer := fake.puts[0].Body.(*bytes.Reader); isBuffer {
+		t.Fatalf("Put should stream the *os.File directly, not a pre-buffered reader")
+	}
+}
+
+func TestS3ProviderPutReportsProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("b"), 4096)
+	f := newTestFile(t, content)
+	fake := &fakeS3API{}
+
+	var calls []int64
+	cfg := Config{
+		Bucket: "my-bucket",
+		ProgressFunc: func(bytesWritten, total int64) {
+			calls = append(calls, bytesWritten)
+			if total != int64(len(content)) {
+				t.Errorf("progress total = %d, want %d", total, len(content))
+			}
+		},
+	}
+	p := newS3Provider(cfg, fake)
+
+	if _, err := p.Put(context.Background(), "build/app.ipa", f, "application/octet-stream", "public-read"); err != nil {
+		t.Fatalf("Put returned error: %v", err)
+	}
+
+	if len(calls) == 0 {
+		t.Fatal("expected ProgressFunc to be called at least once")
+	}
+	if last := calls[len(calls)-1]; last != int64(len(content)) {
+		t.Fatalf("final progress call = %d, want %d", last, len(content))
+	}
+}
+
+func TestS3ProviderPutRetriesTransientErrors(t *testing.T) {
+	content := []byte("retry me")
+	f := newTestFile(t, content)
+	fake := &fakeS3API{failTimes: 2}
+	p := newS3Provider(Config{Bucket: "my-bucket"}, fake)
+
+	if _, err := p.Put(context.Background(), "build/app.ipa", f, "application/octet-stream", "public-read"); err != nil {
+		t.Fatalf("Put should succeed after retrying transient errors, got: %v", err)
+	}
+	if len(fake.puts) != 1 {
+		t.Fatalf("expected the retried call to eventually succeed exactly once, got %d successes", len(fake.puts))
+	}
+}
@@ -0,0 +1,283 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	htmltemp "html/template"
+
+	"github.com/bzon/gota/parser"
+)
+
+// UploadMode controls who can reach an uploaded asset.
+type UploadMode string
+
+const (
+	// ModePublicRead makes uploaded assets world-readable, the default.
+	ModePublicRead UploadMode = "public-read"
+	// ModePrivate keeps uploaded assets private and serves them through
+	// presigned URLs instead.
+	ModePrivate UploadMode = "private"
+)
+
+// DefaultPresignExpiry is how long a presigned URL stays valid when
+// UploadAssets is not given an explicit expiry, long enough to cover a
+// typical mobile build distribution window.
+const DefaultPresignExpiry = 7 * 24 * time.Hour
+
+// Presigner is implemented by providers that can hand out time-limited
+// URLs for private objects. Only S3Provider implements it today.
+type Presigner interface {
+	PresignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// Upload describes a single file to be copied into the configured Provider.
+type Upload struct {
+	SrcFile, DestKey string
+}
+
+// UploadResult is returned by UploadAssets. Uploaded and Skipped partition
+// the same keys Manifest.Assets describes: Skipped holds assets whose
+// SHA256 already matched manifest.json from a previous run (and, when p
+// is a HeadProvider, whose remote object p confirmed still exists; other
+// providers skip on the manifest alone), so re-running UploadAssets
+// for an unchanged build is cheap.
+type UploadResult struct {
+	Assets   []string
+	Uploaded []string
+	Skipped  []string
+	Manifest Manifest
+}
+
+// UploadFile reads upload.SrcFile and hands it to p.Put under upload.DestKey
+// with the given ACL and a best-effort content type.
+func UploadFile(ctx context.Context, p Provider, upload Upload, acl string) (string, error) {
+	f, err := os.Open(upload.SrcFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	contentType, err := detectFileContentType(f, upload.SrcFile)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Put(ctx, upload.DestKey, f, contentType, acl)
+}
+
+// detectFileContentType sniffs f's content type from its first 512 bytes
+// and rewinds f back to the start so the caller can still stream the whole
+// file afterwards.
+func detectFileContentType(f *os.File, srcFile string) (string, error) {
+	head := make([]byte, 512)
+	n, err := f.Read(head)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return DetectContentType(srcFile, head[:n]), nil
+}
+
+// assetURL resolves the URL app.DownloadURL/app.PlistURL should be set to
+// for key: a plain public URL in ModePublicRead, or a presigned URL valid
+// for expiry in ModePrivate.
+func assetURL(p Provider, key string, mode UploadMode, expiry time.Duration) (string, error) {
+	if mode != ModePrivate {
+		return p.PublicURL(key), nil
+	}
+	presigner, ok := p.(Presigner)
+	if !ok {
+		return "", fmt.Errorf("blobstore: %T does not support presigned URLs required by private mode", p)
+	}
+	return presigner.PresignedURL(context.Background(), key, expiry)
+}
+
+// fetchManifest reads and parses a previous manifestKey from p, returning a
+// nil Manifest (not an error) when none exists yet.
+func fetchManifest(ctx context.Context, p Provider, manifestKey string) *Manifest {
+	r, err := p.Get(ctx, manifestKey)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	return &m
+}
+
+// uploadOrSkip uploads upload unless prev already records the same SHA256
+// for upload.DestKey, in which case the upload is skipped. When p is a
+// HeadProvider the skip also requires Head to confirm the remote object
+// still exists, and the entry takes the ETag Head returns; otherwise the
+// manifest is trusted and the previous ETag is reused.
+func uploadOrSkip(ctx context.Context, p Provider, upload Upload, acl string, prev *Manifest) (entry ManifestEntry, url string, skipped bool, err error) {
+	f, err := os.Open(upload.SrcFile)
+	if err != nil {
+		return ManifestEntry{}, "", false, err
+	}
+	defer f.Close()
+
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return ManifestEntry{}, "", false, err
+	}
+
+	contentType, err := detectFileContentType(f, upload.SrcFile)
+	if err != nil {
+		return ManifestEntry{}, "", false, err
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return ManifestEntry{}, "", false, err
+	}
+	sum := hex.EncodeToString(hasher.Sum(nil))
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ManifestEntry{}, "", false, err
+	}
+
+	entry = ManifestEntry{
+		Key:         upload.DestKey,
+		Size:        fileInfo.Size(),
+		SHA256:      sum,
+		ContentType: contentType,
+	}
+
+	if prevEntry, ok := prev.find(upload.DestKey); ok && prevEntry.SHA256 == sum {
+		if hp, ok := p.(HeadProvider); ok {
+			if exists, etag, herr := hp.Head(ctx, upload.DestKey); herr == nil && exists {
+				entry.ETag = etag
+				return entry, p.PublicURL(upload.DestKey), true, nil
+			}
+		} else {
+			// p can't confirm the object is still there; trust the manifest.
+			entry.ETag = prevEntry.ETag
+			return entry, p.PublicURL(upload.DestKey), true, nil
+		}
+	}
+
+	fileURL, err := p.Put(ctx, upload.DestKey, f, contentType, acl)
+	if err != nil {
+		return ManifestEntry{}, "", false, err
+	}
+	if hp, ok := p.(HeadProvider); ok {
+		if _, etag, herr := hp.Head(ctx, upload.DestKey); herr == nil {
+			entry.ETag = etag
+		}
+	}
+	return entry, fileURL, false, nil
+}
+
+// UploadAssets uploads the generated files by the parser package along with
+// the ipa or apk file to p, in the given mode. Assets whose SHA256 matches
+// a previous manifest.json (and, when p is a HeadProvider, whose remote
+// object p confirms still exists) are skipped rather than re-uploaded, making repeat runs over an
+// unchanged build cheap. A zero presignExpiry defaults to
+// DefaultPresignExpiry and is only meaningful in ModePrivate.
+func UploadAssets(p Provider, app *parser.MobileApp, destBaseDir string, mode UploadMode, presignExpiry time.Duration) (*UploadResult, error) {
+	ctx := context.Background()
+	if presignExpiry == 0 {
+		presignExpiry = DefaultPresignExpiry
+	}
+	acl := string(mode)
+
+	// create the site path names and assume the url before uploaded for templating
+	buildDir := destBaseDir + "/" + app.Version + "/" + app.Build
+	appIconPath := buildDir + "/" + parser.AppIconFile
+	appSitePath := buildDir + "/" + filepath.Base(app.File)
+	appIndexHTMLSitePath := buildDir + "/" + parser.IndexHTMLFile
+	manifestPath := buildDir + "/" + ManifestFile
+
+	downloadURL, err := assetURL(p, appSitePath, mode, presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+	app.DownloadURL = downloadURL
+
+	// default directory of assets
+	assetsDir := parser.AndroidAssetsDir
+	// specific for ios
+	var appPlistSitePath string
+	if app.IsIOS() {
+		assetsDir = parser.IOSAssetsDir
+		appPlistSitePath = buildDir + "/" + parser.IOSPlistFile
+		plistURL, err := assetURL(p, appPlistSitePath, mode, presignExpiry)
+		if err != nil {
+			return nil, err
+		}
+		app.PlistURL = htmltemp.URL(plistURL)
+	}
+
+	// create the assets; index.html/plist are generated from app.DownloadURL
+	// and app.PlistURL above, so they already embed the right URLs for mode
+	if err := app.GenerateAssets(); err != nil {
+		return nil, err
+	}
+
+	uploads := []Upload{
+		{assetsDir + "/" + parser.AppIconFile, appIconPath},
+		{assetsDir + "/" + parser.VersionJsonFile, destBaseDir + "/" + app.Version + "/" + parser.VersionJsonFile},
+		{assetsDir + "/" + parser.IndexHTMLFile, appIndexHTMLSitePath},
+		{app.File, appSitePath},
+	}
+
+	if app.IsIOS() {
+		uploads = append(uploads, Upload{assetsDir + "/" + parser.IOSPlistFile, appPlistSitePath})
+	}
+
+	prev := fetchManifest(ctx, p, manifestPath)
+	result := &UploadResult{Manifest: Manifest{Version: app.Version, Build: app.Build}}
+
+	for _, upload := range uploads {
+		entry, fileURL, skipped, err := uploadOrSkip(ctx, p, upload, acl, prev)
+		if err != nil {
+			return result, err
+		}
+
+		// Ensure the returned string is a decoded url
+		decodedURL, err := url.QueryUnescape(fileURL)
+		if err != nil {
+			return result, err
+		}
+
+		result.Assets = append(result.Assets, decodedURL)
+		result.Manifest.Assets = append(result.Manifest.Assets, entry)
+		if skipped {
+			result.Skipped = append(result.Skipped, upload.DestKey)
+		} else {
+			result.Uploaded = append(result.Uploaded, upload.DestKey)
+		}
+	}
+
+	manifestJSON, err := json.Marshal(result.Manifest)
+	if err != nil {
+		return result, err
+	}
+	if _, err := p.Put(ctx, manifestPath, bytes.NewReader(manifestJSON), "application/json", acl); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,182 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+var errNotFound = errors.New("blobstore: object not found")
+
+// memProvider is an in-memory Provider used to exercise the manifest-based
+// skip logic without talking to any real backend.
+type memProvider struct {
+	objects map[string][]byte
+	puts    int
+	missing map[string]bool // keys Head should report as absent
+}
+
+func newMemProvider() *memProvider {
+	return &memProvider{objects: map[string][]byte{}, missing: map[string]bool{}}
+}
+
+func (m *memProvider) Put(ctx context.Context, key string, r io.Reader, contentType, acl string) (string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	m.objects[key] = data
+	m.puts++
+	return "https://example.test/" + key, nil
+}
+
+func (m *memProvider) Delete(ctx context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memProvider) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, errNotFound
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memProvider) PublicURL(key string) string {
+	return "https://example.test/" + key
+}
+
+func (m *memProvider) Head(ctx context.Context, key string) (bool, string, error) {
+	if m.missing[key] {
+		return false, "", nil
+	}
+	_, ok := m.objects[key]
+	return ok, "etag-" + key, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadOrSkipUploadsWhenNoPriorManifest(t *testing.T) {
+	content := []byte("build contents")
+	f := newTestFile(t, content)
+	p := newMemProvider()
+
+	entry, _, skipped, err := uploadOrSkip(context.Background(), p, Upload{SrcFile: f.Name(), DestKey: "v1/1/app.ipa"}, "public-read", nil)
+	if err != nil {
+		t.Fatalf("uploadOrSkip returned error: %v", err)
+	}
+	if skipped {
+		t.Fatal("expected upload to proceed without a prior manifest")
+	}
+	if p.puts != 1 {
+		t.Fatalf("expected exactly one Put call, got %d", p.puts)
+	}
+	if entry.SHA256 != sha256Hex(content) {
+		t.Fatalf("manifest entry sha256 = %q, want %q", entry.SHA256, sha256Hex(content))
+	}
+	if !bytes.Equal(p.objects["v1/1/app.ipa"], content) {
+		t.Fatalf("uploaded object = %q, want %q", p.objects["v1/1/app.ipa"], content)
+	}
+}
+
+// TestUploadOrSkipStreamsFullContentViaS3Provider guards against the
+// zero-byte upload bug (aws-sdk-go #1962): uploadOrSkip must not leave f
+// drained at EOF before handing it to Provider.Put.
+func TestUploadOrSkipStreamsFullContentViaS3Provider(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 4096)
+	f := newTestFile(t, content)
+	fake := &fakeS3API{}
+	p := newS3Provider(Config{Bucket: "my-bucket"}, fake)
+
+	_, _, skipped, err := uploadOrSkip(context.Background(), p, Upload{SrcFile: f.Name(), DestKey: "v1/1/app.ipa"}, "public-read", nil)
+	if err != nil {
+		t.Fatalf("uploadOrSkip returned error: %v", err)
+	}
+	if skipped {
+		t.Fatal("expected upload to proceed without a prior manifest")
+	}
+	if len(fake.bodies) != 1 {
+		t.Fatalf("expected exactly one PutObject call, got %d", len(fake.bodies))
+	}
+	if !bytes.Equal(fake.bodies[0], content) {
+		t.Fatalf("uploaded body len = %d, want %d (file was drained before Put)", len(fake.bodies[0]), len(content))
+	}
+}
+
+func TestUploadOrSkipSkipsWhenDigestMatchesAndObjectExists(t *testing.T) {
+	content := []byte("unchanged build")
+	f := newTestFile(t, content)
+	p := newMemProvider()
+	key := "v1/1/app.ipa"
+	p.objects[key] = content // simulate a previous run's upload
+
+	prev := &Manifest{Assets: []ManifestEntry{{Key: key, SHA256: sha256Hex(content)}}}
+
+	_, _, skipped, err := uploadOrSkip(context.Background(), p, Upload{SrcFile: f.Name(), DestKey: key}, "public-read", prev)
+	if err != nil {
+		t.Fatalf("uploadOrSkip returned error: %v", err)
+	}
+	if !skipped {
+		t.Fatal("expected upload to be skipped when the digest matches and the object exists")
+	}
+	if p.puts != 0 {
+		t.Fatalf("expected no Put calls, got %d", p.puts)
+	}
+}
+
+func TestUploadOrSkipReuploadsWhenRemoteObjectMissing(t *testing.T) {
+	content := []byte("unchanged build")
+	f := newTestFile(t, content)
+	p := newMemProvider()
+	key := "v1/1/app.ipa"
+	p.missing[key] = true
+
+	prev := &Manifest{Assets: []ManifestEntry{{Key: key, SHA256: sha256Hex(content)}}}
+
+	_, _, skipped, err := uploadOrSkip(context.Background(), p, Upload{SrcFile: f.Name(), DestKey: key}, "public-read", prev)
+	if err != nil {
+		t.Fatalf("uploadOrSkip returned error: %v", err)
+	}
+	if skipped {
+		t.Fatal("expected a re-upload when Head reports the remote object missing")
+	}
+	if p.puts != 1 {
+		t.Fatalf("expected exactly one Put call, got %d", p.puts)
+	}
+	if !bytes.Equal(p.objects[key], content) {
+		t.Fatalf("re-uploaded object = %q, want %q", p.objects[key], content)
+	}
+}
+
+func TestUploadOrSkipReuploadsWhenDigestChanged(t *testing.T) {
+	content := []byte("new build")
+	f := newTestFile(t, content)
+	p := newMemProvider()
+	key := "v1/1/app.ipa"
+	p.objects[key] = []byte("old build")
+
+	prev := &Manifest{Assets: []ManifestEntry{{Key: key, SHA256: sha256Hex([]byte("old build"))}}}
+
+	_, _, skipped, err := uploadOrSkip(context.Background(), p, Upload{SrcFile: f.Name(), DestKey: key}, "public-read", prev)
+	if err != nil {
+		t.Fatalf("uploadOrSkip returned error: %v", err)
+	}
+	if skipped {
+		t.Fatal("expected a re-upload when the local file's digest changed")
+	}
+	if p.puts != 1 {
+		t.Fatalf("expected exactly one Put call, got %d", p.puts)
+	}
+	if !bytes.Equal(p.objects[key], content) {
+		t.Fatalf("re-uploaded object = %q, want %q", p.objects[key], content)
+	}
+}